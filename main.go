@@ -1,265 +1,256 @@
 package main
 
 import (
+    "bytes"
+    "flag"
     "fmt"
+    "io"
+    "io/fs"
     "os"
     "path/filepath"
-    "regexp"
-    "strconv"
+    "strings"
 
     goyaml "gopkg.in/yaml.v3"
 )
 
 type ValidationError struct {
-    Msg  string
-    Line int
+    Msg    string
+    Line   int
+    Column int
+    Rule   string
 }
 
 func main() {
-    if len(os.Args) != 2 {
-        fmt.Println("usage: yamlvalidator <file>")
-        os.Exit(2)
+    schemaPath := flag.String("schema", "", "path to a JSON Schema file overriding the built-in Pod schema")
+    configPath := flag.String("config", "", "path to a policy YAML file (allowed registries, required labels, ...)")
+    dumpDefaultPolicy := flag.Bool("dump-default-policy", false, "print the built-in policy as YAML and exit")
+    format := flag.String("o", "text", "output format: text, json, or sarif")
+    disable := flag.String("disable", "", "comma-separated list of rule IDs to suppress")
+    flag.Parse()
+
+    if *dumpDefaultPolicy {
+        b, err := goyaml.Marshal(defaultPolicy())
+        if err != nil {
+            fmt.Printf("%s\n", err)
+            os.Exit(2)
+        }
+        os.Stdout.Write(b)
+        os.Exit(0)
     }
-    path := os.Args[1]
-    file := filepath.Base(path)
 
-    b, err := os.ReadFile(path)
-    if err != nil {
-        fmt.Printf("%s: %s\n", file, err)
+    if flag.NArg() != 1 {
+        fmt.Println("usage: yamlvalidator [-schema file] [-config policy.yaml] [-o text|json|sarif] [-disable rule1,rule2] <file|dir>")
         os.Exit(2)
     }
+    path := flag.Arg(0)
 
-    var root goyaml.Node
-    if err := goyaml.Unmarshal(b, &root); err != nil {
-        fmt.Printf("%s: %s\n", file, err)
+    if *format != "text" && *format != "json" && *format != "sarif" {
+        fmt.Printf("unsupported output format '%s'\n", *format)
         os.Exit(2)
     }
 
-    if len(root.Content) == 0 {
-        fmt.Printf("%s: document is empty\n", file)
-        os.Exit(1)
+    podSchema, err := loadPodSchema(*schemaPath)
+    if err != nil {
+        fmt.Printf("%s\n", err)
+        os.Exit(2)
     }
 
-    errs := validatePod(root.Content[0])
-    if len(errs) > 0 {
-        for _, e := range errs {
-            if e.Line > 0 {
-                fmt.Printf("%s:%d %s\n", file, e.Line, e.Msg)
-            } else {
-                fmt.Printf("%s: %s\n", file, e.Msg)
-            }
+    if *configPath != "" {
+        policy, err := loadPolicy(*configPath)
+        if err != nil {
+            fmt.Printf("%s\n", err)
+            os.Exit(2)
         }
-        os.Exit(1)
+        applyPolicy(podSchema, policy)
     }
 
-    os.Exit(0)
-}
-
-func getMapValue(n *goyaml.Node, key string) *goyaml.Node {
-    if n == nil || n.Kind != goyaml.MappingNode {
-        return nil
-    }
-    for i := 0; i < len(n.Content)-1; i += 2 {
-        if n.Content[i].Value == key {
-            return n.Content[i+1]
-        }
+    files, err := collectYAMLFiles(path)
+    if err != nil {
+        fmt.Printf("%s: %s\n", path, err)
+        os.Exit(2)
     }
-    return nil
-}
-
-func validatePod(n *goyaml.Node) []ValidationError {
-    var r []ValidationError
 
-    nAPI := getMapValue(n, "apiVersion")
-    if nAPI == nil {
-        r = append(r, ValidationError{"apiVersion is required", 0})
-    } else if nAPI.Value != "v1" {
-        r = append(r, ValidationError{fmt.Sprintf("apiVersion has unsupported value '%s'", nAPI.Value), nAPI.Line})
-    }
+    disabled := parseDisabledRules(*disable)
 
-    nKind := getMapValue(n, "kind")
-    if nKind == nil {
-        r = append(r, ValidationError{"kind is required", 0})
-    } else if nKind.Value != "Pod" {
-        r = append(r, ValidationError{fmt.Sprintf("kind has unsupported value '%s'", nKind.Value), nKind.Line})
+    var findings []Finding
+    for _, f := range files {
+        findings = append(findings, validateFile(f, podSchema, disabled)...)
     }
 
-    nMeta := getMapValue(n, "metadata")
-    if nMeta == nil {
-        r = append(r, ValidationError{"metadata is required", 0})
-    } else {
-        r = append(r, validateMetadata(nMeta)...)
+    switch *format {
+    case "json":
+        printJSON(findings)
+    case "sarif":
+        printSARIF(findings)
+    default:
+        printText(findings)
     }
 
-    nSpec := getMapValue(n, "spec")
-    if nSpec == nil {
-        r = append(r, ValidationError{"spec is required", 0})
-    } else {
-        r = append(r, validateSpec(nSpec)...)
+    if len(findings) > 0 {
+        os.Exit(1)
     }
-
-    return r
+    os.Exit(0)
 }
 
-func validateMetadata(n *goyaml.Node) []ValidationError {
-    var r []ValidationError
-    nName := getMapValue(n, "name")
-    if nName == nil || nName.Value == "" {
-        line := 0
-        if nName != nil {
-            line = nName.Line
+func parseDisabledRules(flagValue string) map[string]bool {
+    disabled := map[string]bool{}
+    for _, rule := range strings.Split(flagValue, ",") {
+        rule = strings.TrimSpace(rule)
+        if rule != "" {
+            disabled[rule] = true
         }
-        r = append(r, ValidationError{"name is required", line})
     }
-    return r
+    return disabled
 }
 
-func validateSpec(n *goyaml.Node) []ValidationError {
-    var r []ValidationError
-
-    nOS := getMapValue(n, "os")
-    if nOS != nil && nOS.Value != "linux" && nOS.Value != "windows" {
-        r = append(r, ValidationError{fmt.Sprintf("os has unsupported value '%s'", nOS.Value), nOS.Line})
+func loadPodSchema(schemaPath string) (*RootSchema, error) {
+    if schemaPath == "" {
+        return defaultPodSchema()
     }
 
-    nCont := getMapValue(n, "containers")
-    if nCont == nil || nCont.Kind != goyaml.SequenceNode {
-        r = append(r, ValidationError{"spec.containers is required", 0})
-        return r
-    }
-
-    seen := map[string]bool{}
-    for _, c := range nCont.Content {
-        r = append(r, validateContainer(c, seen)...)
+    b, err := os.ReadFile(schemaPath)
+    if err != nil {
+        return nil, err
     }
-
-    return r
+    return loadSchema(b)
 }
 
-func validateContainer(n *goyaml.Node, seen map[string]bool) []ValidationError {
-    var r []ValidationError
-
-    nName := getMapValue(n, "name")
-    if nName == nil || nName.Value == "" {
-        line := 0
-        if nName != nil {
-            line = nName.Line
-        }
-        r = append(r, ValidationError{"name is required", line})
-    } else if seen[nName.Value] {
-        r = append(r, ValidationError{fmt.Sprintf("containers.name has invalid format '%s'", nName.Value), nName.Line})
-    } else {
-        seen[nName.Value] = true
+// collectYAMLFiles returns path itself if it is a file, or every .yaml/.yml
+// file beneath it if it is a directory.
+func collectYAMLFiles(path string) ([]string, error) {
+    info, err := os.Stat(path)
+    if err != nil {
+        return nil, err
     }
 
-    nImg := getMapValue(n, "image")
-    if nImg == nil {
-        r = append(r, ValidationError{"image is required", 0})
-    } else if !regexp.MustCompile(`^registry\.bigbrother\.io\/[^:]+:[^:]+$`).MatchString(nImg.Value) {
-        r = append(r, ValidationError{fmt.Sprintf("image has invalid format '%s'", nImg.Value), nImg.Line})
+    if !info.IsDir() {
+        return []string{path}, nil
     }
 
-    nPorts := getMapValue(n, "ports")
-    if nPorts != nil && nPorts.Kind == goyaml.SequenceNode {
-        for _, p := range nPorts.Content {
-            r = append(r, validateContainerPort(p)...)
+    var files []string
+    err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+        if err != nil {
+            return err
         }
-    }
-
-    for _, pname := range []string{"readinessProbe", "livenessProbe"} {
-        pn := getMapValue(n, pname)
-        if pn != nil {
-            r = append(r, validateProbe(pn, pname)...)
+        if d.IsDir() {
+            return nil
         }
+        ext := strings.ToLower(filepath.Ext(p))
+        if ext == ".yaml" || ext == ".yml" {
+            files = append(files, p)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
+    return files, nil
+}
 
-    nRes := getMapValue(n, "resources")
-    if nRes == nil {
-        r = append(r, ValidationError{"resources is required", 0})
-    } else {
-        r = append(r, validateResources(nRes)...)
+// validateFile decodes every document in the YAML stream at path and
+// validates each one, returning a Finding per surviving ValidationError
+// (after -disable filtering) tagged with its file and document index. The
+// path is reported as given (not just its basename) so that files with
+// colliding names in different directories, as in a kustomize-style
+// base/overlay layout, can still be told apart.
+func validateFile(path string, podSchema *RootSchema, disabled map[string]bool) []Finding {
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return []Finding{{File: path, NoDocIndex: true, Rule: "yamlvalidator.read", Msg: err.Error()}}
     }
 
-    return r
-}
-
-func validateContainerPort(n *goyaml.Node) []ValidationError {
-    var r []ValidationError
+    var findings []Finding
+    sawContent := false
+    dec := goyaml.NewDecoder(bytes.NewReader(b))
+    for docIndex := 0; ; docIndex++ {
+        var doc goyaml.Node
+        err := dec.Decode(&doc)
+        if err == io.EOF {
+            // Only a stream that never produced real content - a zero-byte
+            // file, or one consisting of nothing but "---" separators - is
+            // reported as empty; a trailing separator after a real document
+            // is not.
+            if !sawContent {
+                findings = append(findings, Finding{File: path, NoDocIndex: true, Rule: "yamlvalidator.document", Msg: "document is empty"})
+            }
+            break
+        }
+        if err != nil {
+            findings = append(findings, Finding{File: path, Doc: docIndex, Rule: "yamlvalidator.parse", Msg: err.Error()})
+            break
+        }
 
-    cp := getMapValue(n, "containerPort")
-    if cp == nil {
-        r = append(r, ValidationError{"containerPort is required", 0})
-    } else if cp.Tag != "!!int" {
-        r = append(r, ValidationError{"containerPort must be int", cp.Line})
-    } else {
-        v, _ := strconv.Atoi(cp.Value)
-        if v <= 0 || v >= 65536 {
-            r = append(r, ValidationError{"containerPort value out of range", cp.Line})
+        // A lone `!!null` scalar is how goyaml represents an empty
+        // document, e.g. a trailing "---" separator at end of file. It
+        // isn't a document to validate, and isn't an error either -
+        // kustomize/helm output routinely ends with one.
+        if isEmptyDocument(&doc) {
+            continue
+        }
+        sawContent = true
+
+        root := doc.Content[0]
+        if kindNode := getMapValue(root, "kind"); kindNode != nil && !schemaDeclaresKind(podSchema, kindNode.Value) {
+            // A kustomize bundle routinely mixes kinds (Service, ConfigMap,
+            // ...) the configured schema doesn't cover; skip those rather
+            // than failing the whole bundle.
+            fmt.Fprintf(os.Stderr, "%s: skipping document %d with kind '%s': not covered by the configured schema\n", path, docIndex, kindNode.Value)
+            continue
         }
-    }
 
-    proto := getMapValue(n, "protocol")
-    if proto != nil && proto.Value != "TCP" && proto.Value != "UDP" {
-        r = append(r, ValidationError{fmt.Sprintf("protocol has unsupported value '%s'", proto.Value), proto.Line})
+        for _, e := range validateDocument(root, podSchema) {
+            if disabled[e.Rule] {
+                continue
+            }
+            findings = append(findings, Finding{File: path, Doc: docIndex, Line: e.Line, Column: e.Column, Rule: e.Rule, Msg: e.Msg})
+        }
     }
 
-    return r
+    return findings
 }
 
-func validateProbe(n *goyaml.Node, parent string) []ValidationError {
-    var r []ValidationError
-
-    hg := getMapValue(n, "httpGet")
-    if hg == nil {
-        r = append(r, ValidationError{fmt.Sprintf("%s.httpGet is required", parent), 0})
-        return r
+// isEmptyDocument reports whether a decoded document has no real content:
+// either no content node at all, or a single `!!null` scalar, which is
+// how goyaml represents a document consisting only of a "---" separator.
+func isEmptyDocument(doc *goyaml.Node) bool {
+    if len(doc.Content) == 0 {
+        return true
     }
+    c := doc.Content[0]
+    return len(doc.Content) == 1 && c.Kind == goyaml.ScalarNode && c.Tag == "!!null"
+}
 
-    p := getMapValue(hg, "path")
-    if p == nil || len(p.Value) == 0 || p.Value[0] != '/' {
-        line := 0
-        if p != nil {
-            line = p.Line
-        }
-        r = append(r, ValidationError{fmt.Sprintf("%s.httpGet.path has invalid format '%s'", parent, p.Value), line})
+// schemaDeclaresKind reports whether podSchema's "kind" field would accept
+// the given value, i.e. it has no enum constraint (anything goes) or the
+// value is one of the enum's entries.
+func schemaDeclaresKind(podSchema *RootSchema, kind string) bool {
+    kindSchema := podSchema.Properties["kind"]
+    if kindSchema == nil || len(kindSchema.Enum) == 0 {
+        return true
     }
+    return contains(kindSchema.Enum, kind)
+}
 
-    port := getMapValue(hg, "port")
-    if port == nil || port.Tag != "!!int" {
-        line := 0
-        if port != nil {
-            line = port.Line
-        }
-        r = append(r, ValidationError{fmt.Sprintf("%s.httpGet.port must be int", parent), line})
-    } else {
-        v, _ := strconv.Atoi(port.Value)
-        if v <= 0 || v >= 65536 {
-            r = append(r, ValidationError{fmt.Sprintf("%s.httpGet.port value out of range", parent), port.Line})
-        }
+// validateDocument validates a document against podSchema. Which kinds are
+// accepted, and what "kind has unsupported value" looks like, is entirely
+// up to the schema's own "kind" enum - this lets a -schema file add new
+// kinds (Deployment, Service, ConfigMap, ...) without touching Go code.
+func validateDocument(n *goyaml.Node, podSchema *RootSchema) []ValidationError {
+    if getMapValue(n, "kind") == nil {
+        return []ValidationError{{Msg: "kind is required", Rule: "yamlvalidator.kind"}}
     }
 
-    return r
+    return validateAgainstSchema(n, &podSchema.Schema, podSchema.Definitions, "")
 }
 
-func validateResources(n *goyaml.Node) []ValidationError {
-    var r []ValidationError
-
-    for _, section := range []string{"requests", "limits"} {
-        ns := getMapValue(n, section)
-        if ns == nil {
-            continue
-        }
-
-        cpu := getMapValue(ns, "cpu")
-        if cpu != nil && cpu.Tag != "!!int" {
-            r = append(r, ValidationError{"cpu must be int", cpu.Line})
-        }
-
-        mem := getMapValue(ns, "memory")
-        if mem != nil && !regexp.MustCompile(`^\d+(Ki|Mi|Gi)$`).MatchString(mem.Value) {
-            r = append(r, ValidationError{fmt.Sprintf("memory has invalid format '%s'", mem.Value), mem.Line})
+func getMapValue(n *goyaml.Node, key string) *goyaml.Node {
+    if n == nil || n.Kind != goyaml.MappingNode {
+        return nil
+    }
+    for i := 0; i < len(n.Content)-1; i += 2 {
+        if n.Content[i].Value == key {
+            return n.Content[i+1]
         }
     }
-
-    return r
+    return nil
 }
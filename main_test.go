@@ -0,0 +1,222 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "testing"
+
+    goyaml "gopkg.in/yaml.v3"
+)
+
+func TestCollectYAMLFilesFile(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "pod.yaml")
+    if err := os.WriteFile(path, []byte("kind: Pod\n"), 0o644); err != nil {
+        t.Fatalf("write file: %v", err)
+    }
+
+    files, err := collectYAMLFiles(path)
+    if err != nil {
+        t.Fatalf("collectYAMLFiles: %v", err)
+    }
+    if len(files) != 1 || files[0] != path {
+        t.Fatalf("expected [%s], got %v", path, files)
+    }
+}
+
+func TestCollectYAMLFilesDirectoryRecurses(t *testing.T) {
+    dir := t.TempDir()
+    base := filepath.Join(dir, "base")
+    overlay := filepath.Join(dir, "overlays", "prod")
+    if err := os.MkdirAll(base, 0o755); err != nil {
+        t.Fatalf("mkdir base: %v", err)
+    }
+    if err := os.MkdirAll(overlay, 0o755); err != nil {
+        t.Fatalf("mkdir overlay: %v", err)
+    }
+
+    baseFile := filepath.Join(base, "deployment.yaml")
+    overlayFile := filepath.Join(overlay, "deployment.yaml")
+    readme := filepath.Join(dir, "README.md")
+    if err := os.WriteFile(baseFile, []byte("kind: Pod\n"), 0o644); err != nil {
+        t.Fatalf("write base file: %v", err)
+    }
+    if err := os.WriteFile(overlayFile, []byte("kind: Pod\n"), 0o644); err != nil {
+        t.Fatalf("write overlay file: %v", err)
+    }
+    if err := os.WriteFile(readme, []byte("not yaml\n"), 0o644); err != nil {
+        t.Fatalf("write readme: %v", err)
+    }
+
+    files, err := collectYAMLFiles(dir)
+    if err != nil {
+        t.Fatalf("collectYAMLFiles: %v", err)
+    }
+    sort.Strings(files)
+
+    want := []string{baseFile, overlayFile}
+    sort.Strings(want)
+    if len(files) != len(want) {
+        t.Fatalf("expected %v, got %v", want, files)
+    }
+    for i := range want {
+        if files[i] != want[i] {
+            t.Errorf("expected %v, got %v", want, files)
+            break
+        }
+    }
+}
+
+func TestValidateFileReportsFullPathNotBasename(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "overlays", "prod", "deployment.yaml")
+    if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+        t.Fatalf("mkdir: %v", err)
+    }
+    if err := os.WriteFile(path, []byte("apiVersion: v1\nkind: Pod\n"), 0o644); err != nil {
+        t.Fatalf("write file: %v", err)
+    }
+
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    findings := validateFile(path, podSchema, nil)
+    if len(findings) == 0 {
+        t.Fatalf("expected findings for a Pod missing metadata/spec")
+    }
+    for _, f := range findings {
+        if f.File != path {
+            t.Errorf("expected Finding.File %q (full path), got %q", path, f.File)
+        }
+    }
+}
+
+func TestValidateFileTrailingSeparatorIsNotEmpty(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "trailing.yaml")
+    content := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\nspec:\n  containers:\n  - name: app\n    image: registry.bigbrother.io/app:v1\n    resources: {}\n---\n"
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatalf("write file: %v", err)
+    }
+
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    findings := validateFile(path, podSchema, nil)
+    if len(findings) != 0 {
+        t.Fatalf("expected a valid Pod with a trailing separator to produce no findings, got %+v", findings)
+    }
+}
+
+func TestValidateFileOnlySeparatorsIsReportedEmpty(t *testing.T) {
+    for _, content := range []string{"---\n", "---\n---\n"} {
+        dir := t.TempDir()
+        path := filepath.Join(dir, "onlysep.yaml")
+        if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+            t.Fatalf("write file: %v", err)
+        }
+
+        podSchema, err := defaultPodSchema()
+        if err != nil {
+            t.Fatalf("defaultPodSchema: %v", err)
+        }
+
+        findings := validateFile(path, podSchema, nil)
+        if len(findings) != 1 || !findings[0].NoDocIndex || findings[0].Msg != "document is empty" {
+            t.Fatalf("content %q: expected a single 'document is empty' finding, got %+v", content, findings)
+        }
+    }
+}
+
+func TestValidateFileZeroByteFileIsReportedEmpty(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "empty.yaml")
+    if err := os.WriteFile(path, nil, 0o644); err != nil {
+        t.Fatalf("write file: %v", err)
+    }
+
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    findings := validateFile(path, podSchema, nil)
+    if len(findings) != 1 || !findings[0].NoDocIndex || findings[0].Msg != "document is empty" {
+        t.Fatalf("expected a single 'document is empty' finding, got %+v", findings)
+    }
+}
+
+func TestValidateFileSkipsKindsTheSchemaDoesNotDeclare(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "bundle.yaml")
+    content := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: app\nspec:\n  containers:\n  - name: app\n    image: registry.bigbrother.io/app:v1\n    resources: {}\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: app\n"
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatalf("write file: %v", err)
+    }
+
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    findings := validateFile(path, podSchema, nil)
+    if len(findings) != 0 {
+        t.Fatalf("expected the Service document to be skipped rather than fail the bundle, got %+v", findings)
+    }
+}
+
+func TestIsEmptyDocument(t *testing.T) {
+    decode := func(s string) *goyaml.Node {
+        dec := goyaml.NewDecoder(strings.NewReader(s))
+        var doc goyaml.Node
+        if err := dec.Decode(&doc); err != nil {
+            t.Fatalf("decode %q: %v", s, err)
+        }
+        return &doc
+    }
+
+    if !isEmptyDocument(decode("---\n")) {
+        t.Errorf("expected a lone '---' separator to decode as an empty document")
+    }
+    if isEmptyDocument(decode("kind: Pod\n")) {
+        t.Errorf("expected a real document to not be empty")
+    }
+}
+
+func TestSchemaDeclaresKind(t *testing.T) {
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    if !schemaDeclaresKind(podSchema, "Pod") {
+        t.Errorf("expected Pod to be declared by the default schema")
+    }
+    if schemaDeclaresKind(podSchema, "Service") {
+        t.Errorf("expected Service to not be declared by the default schema")
+    }
+
+    unconstrained := &RootSchema{Schema: Schema{Type: "object"}}
+    if !schemaDeclaresKind(unconstrained, "AnythingGoes") {
+        t.Errorf("expected a schema with no kind enum to declare every kind")
+    }
+}
+
+func TestValidateDocumentRequiresKind(t *testing.T) {
+    podSchema, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    n := parseNode(t, "apiVersion: v1\n")
+    errs := validateDocument(n, podSchema)
+    if len(errs) != 1 || errs[0].Rule != "yamlvalidator.kind" {
+        t.Fatalf("expected a single 'kind is required' error, got %+v", errs)
+    }
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+// Finding is a ValidationError tagged with the file and document it came
+// from, ready to be rendered in any of the supported output formats.
+type Finding struct {
+    File string
+    Doc  int
+    // NoDocIndex marks findings about the file as a whole rather than one
+    // document in it (a read error, or a stream with no real content),
+    // which text mode reports without a doc index.
+    NoDocIndex bool
+    Line       int
+    Column     int
+    Rule       string
+    Msg        string
+}
+
+func printText(findings []Finding) {
+    for _, f := range findings {
+        switch {
+        case f.NoDocIndex:
+            fmt.Printf("%s: %s\n", f.File, f.Msg)
+        case f.Line > 0:
+            fmt.Printf("%s:%d:%d %s\n", f.File, f.Doc, f.Line, f.Msg)
+        default:
+            fmt.Printf("%s:%d %s\n", f.File, f.Doc, f.Msg)
+        }
+    }
+}
+
+type jsonFinding struct {
+    File     string `json:"file"`
+    Line     int    `json:"line"`
+    Column   int    `json:"column"`
+    Rule     string `json:"rule"`
+    Message  string `json:"message"`
+    Severity string `json:"severity"`
+}
+
+func printJSON(findings []Finding) {
+    out := make([]jsonFinding, 0, len(findings))
+    for _, f := range findings {
+        out = append(out, jsonFinding{
+            File:     f.File,
+            Line:     f.Line,
+            Column:   f.Column,
+            Rule:     f.Rule,
+            Message:  f.Msg,
+            Severity: "error",
+        })
+    }
+
+    enc := json.NewEncoder(os.Stdout)
+    enc.SetIndent("", "  ")
+    enc.Encode(out)
+}
+
+// SARIF 2.1.0 subset: enough to report ruleId, message, and physical
+// location so the results show up in GitHub/GitLab code scanning.
+type sarifLog struct {
+    Schema  string     `json:"$schema"`
+    Version string     `json:"version"`
+    Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+    Tool    sarifTool     `json:"tool"`
+    Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+    Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+    Name    string `json:"name"`
+    Version string `json:"version"`
+}
+
+type sarifResult struct {
+    RuleID    string          `json:"ruleId"`
+    Level     string          `json:"level"`
+    Message   sarifMessage    `json:"message"`
+    Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+    Text string `json:"text"`
+}
+
+type sarifLocation struct {
+    PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+    ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+    Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+    URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+    StartLine   int `json:"startLine"`
+    StartColumn int `json:"startColumn,omitempty"`
+}
+
+func printSARIF(findings []Finding) {
+    results := make([]sarifResult, 0, len(findings))
+    for _, f := range findings {
+        line := f.Line
+        if line <= 0 {
+            line = 1
+        }
+        results = append(results, sarifResult{
+            RuleID: f.Rule,
+            Level:  "error",
+            Message: sarifMessage{
+                Text: f.Msg,
+            },
+            Locations: []sarifLocation{{
+                PhysicalLocation: sarifPhysicalLocation{
+                    ArtifactLocation: sarifArtifactLocation{URI: f.File},
+                    Region: sarifRegion{
+                        StartLine:   line,
+                        StartColumn: f.Column,
+                    },
+                },
+            }},
+        })
+    }
+
+    log := sarifLog{
+        Schema:  "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json",
+        Version: "2.1.0",
+        Runs: []sarifRun{{
+            Tool: sarifTool{
+                Driver: sarifDriver{
+                    Name:    "yamlvalidator",
+                    Version: "1.0.0",
+                },
+            },
+            Results: results,
+        }},
+    }
+
+    enc := json.NewEncoder(os.Stdout)
+    enc.SetIndent("", "  ")
+    enc.Encode(log)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+    "encoding/json"
+    "io"
+    "os"
+    "strings"
+    "testing"
+)
+
+// captureStdout runs fn with os.Stdout redirected to a pipe and returns
+// everything written to it.
+func captureStdout(t *testing.T, fn func()) string {
+    t.Helper()
+
+    r, w, err := os.Pipe()
+    if err != nil {
+        t.Fatalf("pipe: %v", err)
+    }
+    orig := os.Stdout
+    os.Stdout = w
+    defer func() { os.Stdout = orig }()
+
+    fn()
+
+    w.Close()
+    out, err := io.ReadAll(r)
+    if err != nil {
+        t.Fatalf("read: %v", err)
+    }
+    return string(out)
+}
+
+func TestPrintTextFormats(t *testing.T) {
+    findings := []Finding{
+        {File: "empty.yaml", NoDocIndex: true, Msg: "document is empty"},
+        {File: "pod.yaml", Doc: 0, Line: 5, Column: 3, Msg: "spec.containers[0].image is required"},
+        {File: "pod.yaml", Doc: 1, Msg: "kind is required"},
+    }
+
+    out := captureStdout(t, func() { printText(findings) })
+    lines := strings.Split(strings.TrimRight(out, "\n"), "\n")
+
+    want := []string{
+        "empty.yaml: document is empty",
+        "pod.yaml:0:5 spec.containers[0].image is required",
+        "pod.yaml:1 kind is required",
+    }
+    if len(lines) != len(want) {
+        t.Fatalf("expected %d lines, got %d: %q", len(want), len(lines), out)
+    }
+    for i, w := range want {
+        if lines[i] != w {
+            t.Errorf("line %d: expected %q, got %q", i, w, lines[i])
+        }
+    }
+}
+
+func TestPrintJSON(t *testing.T) {
+    findings := []Finding{
+        {File: "pod.yaml", Line: 5, Column: 3, Rule: "pod.container.image", Msg: "bad image"},
+    }
+
+    out := captureStdout(t, func() { printJSON(findings) })
+
+    var got []jsonFinding
+    if err := json.Unmarshal([]byte(out), &got); err != nil {
+        t.Fatalf("unmarshal %q: %v", out, err)
+    }
+    if len(got) != 1 {
+        t.Fatalf("expected 1 finding, got %d", len(got))
+    }
+    if got[0].File != "pod.yaml" || got[0].Rule != "pod.container.image" || got[0].Message != "bad image" || got[0].Severity != "error" {
+        t.Errorf("unexpected finding: %+v", got[0])
+    }
+}
+
+func TestPrintSARIF(t *testing.T) {
+    findings := []Finding{
+        {File: "pod.yaml", Rule: "pod.container.image", Msg: "bad image"},
+    }
+
+    out := captureStdout(t, func() { printSARIF(findings) })
+
+    var got sarifLog
+    if err := json.Unmarshal([]byte(out), &got); err != nil {
+        t.Fatalf("unmarshal %q: %v", out, err)
+    }
+    if len(got.Runs) != 1 || len(got.Runs[0].Results) != 1 {
+        t.Fatalf("expected 1 run with 1 result, got %+v", got)
+    }
+    result := got.Runs[0].Results[0]
+    if result.RuleID != "pod.container.image" || result.Message.Text != "bad image" {
+        t.Errorf("unexpected result: %+v", result)
+    }
+    // Line defaults to 1 when a finding has no line information.
+    if result.Locations[0].PhysicalLocation.Region.StartLine != 1 {
+        t.Errorf("expected default StartLine 1, got %d", result.Locations[0].PhysicalLocation.Region.StartLine)
+    }
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+    "os"
+
+    goyaml "gopkg.in/yaml.v3"
+)
+
+// Policy captures the business rules a cluster operator layers on top of
+// the structural Pod schema: which registries and tags are acceptable,
+// which labels and fields are mandatory, and which ports/protocols are
+// allowed. It is loaded from a user-supplied -config YAML file and merged
+// onto defaultPolicy(), so an incomplete file still behaves sensibly.
+type Policy struct {
+    AllowedRegistries     []string `yaml:"allowedRegistries"`
+    ForbiddenTags         []string `yaml:"forbiddenTags"`
+    RequiredLabels        []string `yaml:"requiredLabels"`
+    RequireNamespace      bool     `yaml:"requireNamespace"`
+    MinContainerPort      int      `yaml:"minContainerPort"`
+    MaxContainerPort      int      `yaml:"maxContainerPort"`
+    AllowedProtocols      []string `yaml:"allowedProtocols"`
+    RequireResourceLimits bool     `yaml:"requireResourceLimits"`
+}
+
+// defaultPolicy mirrors the validator's built-in behavior, i.e. what you
+// get when no -config is supplied.
+func defaultPolicy() Policy {
+    return Policy{
+        AllowedRegistries:     []string{`^registry\.bigbrother\.io/[^:]+:[^:]+$`},
+        ForbiddenTags:         nil,
+        RequiredLabels:        nil,
+        RequireNamespace:      false,
+        MinContainerPort:      1,
+        MaxContainerPort:      65535,
+        AllowedProtocols:      []string{"TCP", "UDP"},
+        RequireResourceLimits: false,
+    }
+}
+
+func loadPolicy(path string) (*Policy, error) {
+    p := defaultPolicy()
+
+    b, err := os.ReadFile(path)
+    if err != nil {
+        return nil, err
+    }
+    if err := goyaml.Unmarshal(b, &p); err != nil {
+        return nil, err
+    }
+    return &p, nil
+}
+
+// applyPolicy layers policy onto a loaded Pod schema by reaching into its
+// well-known fields (image, ports, resources, metadata). Missing fields
+// are skipped rather than treated as errors, so a -schema override that
+// doesn't define every field still works with -config.
+func applyPolicy(root *RootSchema, policy *Policy) {
+    applyMetadataPolicy(root.Properties["metadata"], policy)
+
+    container := containerItemSchema(root)
+    if container == nil {
+        return
+    }
+
+    if image := container.Properties["image"]; image != nil {
+        if len(policy.AllowedRegistries) > 0 {
+            image.Patterns = policy.AllowedRegistries
+        }
+        if len(policy.ForbiddenTags) > 0 {
+            image.ForbiddenValues = policy.ForbiddenTags
+        }
+    }
+
+    if ports := container.Properties["ports"]; ports != nil && ports.Items != nil {
+        if cp := ports.Items.Properties["containerPort"]; cp != nil {
+            if policy.MinContainerPort != 0 {
+                min := float64(policy.MinContainerPort)
+                cp.Minimum = &min
+            }
+            if policy.MaxContainerPort != 0 {
+                max := float64(policy.MaxContainerPort)
+                cp.Maximum = &max
+            }
+        }
+        if proto := ports.Items.Properties["protocol"]; proto != nil && len(policy.AllowedProtocols) > 0 {
+            proto.Enum = policy.AllowedProtocols
+        }
+    }
+
+    if res := container.Properties["resources"]; res != nil && policy.RequireResourceLimits {
+        res.Required = []string{"requests", "limits"}
+    }
+}
+
+func applyMetadataPolicy(metadata *Schema, policy *Policy) {
+    if metadata == nil {
+        return
+    }
+
+    if metadata.Properties == nil {
+        metadata.Properties = map[string]*Schema{}
+    }
+
+    if policy.RequireNamespace && !contains(metadata.Required, "namespace") {
+        metadata.Required = append(metadata.Required, "namespace")
+        if metadata.Properties["namespace"] == nil {
+            minLen := 1
+            metadata.Properties["namespace"] = &Schema{Type: "string", MinLength: &minLen, Rule: "pod.metadata.namespace"}
+        }
+    }
+
+    if len(policy.RequiredLabels) > 0 {
+        labels := metadata.Properties["labels"]
+        if labels == nil {
+            labels = &Schema{Type: "object", Rule: "pod.metadata.labels"}
+            metadata.Properties["labels"] = labels
+        }
+        labels.RequiredMapKeys = policy.RequiredLabels
+    }
+}
+
+func containerItemSchema(root *RootSchema) *Schema {
+    spec := root.Properties["spec"]
+    if spec == nil {
+        return nil
+    }
+    containers := spec.Properties["containers"]
+    if containers == nil {
+        return nil
+    }
+    return containers.Items
+}
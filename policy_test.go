@@ -0,0 +1,119 @@
+package main
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestLoadPolicyMergesOntoDefaults(t *testing.T) {
+    dir := t.TempDir()
+    path := filepath.Join(dir, "policy.yaml")
+    if err := os.WriteFile(path, []byte("requiredLabels: [\"team\", \"env\"]\nrequireNamespace: true\n"), 0o644); err != nil {
+        t.Fatalf("write policy file: %v", err)
+    }
+
+    p, err := loadPolicy(path)
+    if err != nil {
+        t.Fatalf("loadPolicy: %v", err)
+    }
+
+    if !p.RequireNamespace {
+        t.Errorf("expected RequireNamespace to be true from the file")
+    }
+    if len(p.RequiredLabels) != 2 || p.RequiredLabels[0] != "team" || p.RequiredLabels[1] != "env" {
+        t.Errorf("expected RequiredLabels from the file, got %v", p.RequiredLabels)
+    }
+
+    // Fields absent from the file fall back to defaultPolicy().
+    def := defaultPolicy()
+    if p.MinContainerPort != def.MinContainerPort || p.MaxContainerPort != def.MaxContainerPort {
+        t.Errorf("expected port bounds to fall back to defaults, got min=%d max=%d", p.MinContainerPort, p.MaxContainerPort)
+    }
+    if len(p.AllowedRegistries) != len(def.AllowedRegistries) || p.AllowedRegistries[0] != def.AllowedRegistries[0] {
+        t.Errorf("expected AllowedRegistries to fall back to defaults, got %v", p.AllowedRegistries)
+    }
+}
+
+func TestApplyPolicyMutatesSchema(t *testing.T) {
+    root, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    policy := defaultPolicy()
+    policy.AllowedRegistries = []string{`^myregistry\.example\.com/.+$`}
+    policy.ForbiddenTags = []string{"latest"}
+    policy.MinContainerPort = 8000
+    policy.MaxContainerPort = 9000
+    policy.AllowedProtocols = []string{"TCP"}
+    policy.RequireResourceLimits = true
+    policy.RequireNamespace = true
+    policy.RequiredLabels = []string{"team"}
+
+    applyPolicy(root, &policy)
+
+    container := containerItemSchema(root)
+    if container == nil {
+        t.Fatalf("containerItemSchema returned nil")
+    }
+
+    image := container.Properties["image"]
+    if len(image.Patterns) != 1 || image.Patterns[0] != policy.AllowedRegistries[0] {
+        t.Errorf("expected image patterns overridden, got %v", image.Patterns)
+    }
+    if len(image.ForbiddenValues) != 1 || image.ForbiddenValues[0] != "latest" {
+        t.Errorf("expected image forbidden tags set, got %v", image.ForbiddenValues)
+    }
+
+    port := container.Properties["ports"].Items.Properties["containerPort"]
+    if *port.Minimum != 8000 || *port.Maximum != 9000 {
+        t.Errorf("expected port bounds overridden, got min=%v max=%v", *port.Minimum, *port.Maximum)
+    }
+
+    proto := container.Properties["ports"].Items.Properties["protocol"]
+    if len(proto.Enum) != 1 || proto.Enum[0] != "TCP" {
+        t.Errorf("expected protocol enum overridden, got %v", proto.Enum)
+    }
+
+    resources := container.Properties["resources"]
+    if len(resources.Required) != 2 {
+        t.Errorf("expected resources.required to list requests and limits, got %v", resources.Required)
+    }
+
+    metadata := root.Properties["metadata"]
+    if !contains(metadata.Required, "namespace") {
+        t.Errorf("expected namespace to be required, got %v", metadata.Required)
+    }
+    if metadata.Properties["namespace"] == nil {
+        t.Errorf("expected a namespace schema to be added")
+    }
+
+    labels := metadata.Properties["labels"]
+    if labels == nil || len(labels.RequiredMapKeys) != 1 || labels.RequiredMapKeys[0] != "team" {
+        t.Errorf("expected labels.x-required-keys to be set, got %+v", labels)
+    }
+}
+
+func TestApplyPolicyNoConfigLeavesSchemaUnchanged(t *testing.T) {
+    root, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+    before, err := defaultPodSchema()
+    if err != nil {
+        t.Fatalf("defaultPodSchema: %v", err)
+    }
+
+    policy := defaultPolicy()
+    applyPolicy(root, &policy)
+
+    container := containerItemSchema(root)
+    beforeContainer := containerItemSchema(before)
+    if container.Properties["image"].Patterns[0] != beforeContainer.Properties["image"].Patterns[0] {
+        t.Errorf("expected image patterns to match the default schema when policy is all-defaults")
+    }
+    if len(container.Properties["resources"].Required) != 0 {
+        t.Errorf("expected resources.required to stay empty without -config, got %v", container.Properties["resources"].Required)
+    }
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+    "fmt"
+    "math"
+    "math/big"
+    "regexp"
+    "strconv"
+)
+
+// quantityPattern matches the Kubernetes resource.Quantity grammar:
+// a signed decimal number followed by an optional binary SI suffix
+// (Ki, Mi, Gi, ...), decimal SI suffix (n, u, m, k, M, G, ...), or a
+// decimal exponent (e.g. e3, E-2).
+var quantityPattern = regexp.MustCompile(`^([+-]?[0-9]+(?:\.[0-9]+)?)(Ki|Mi|Gi|Ti|Pi|Ei|[eE][+-]?[0-9]+|[numkMGTPE]?)$`)
+
+var binarySIMultipliers = map[string]int64{
+    "Ki": 1 << 10,
+    "Mi": 1 << 20,
+    "Gi": 1 << 30,
+    "Ti": 1 << 40,
+    "Pi": 1 << 50,
+    "Ei": 1 << 60,
+}
+
+var decimalSIExponents = map[string]int{
+    "n": -9,
+    "u": -6,
+    "m": -3,
+    "":  0,
+    "k": 3,
+    "M": 6,
+    "G": 9,
+    "T": 12,
+    "P": 15,
+    "E": 18,
+}
+
+// ParseQuantity parses a Kubernetes-style resource quantity (e.g. "500m",
+// "1.5Gi", "2", "129e6") and returns its value normalized to milli-units,
+// so that quantities of the same kind can be compared with plain integer
+// comparison.
+func ParseQuantity(s string) (int64, error) {
+    m := quantityPattern.FindStringSubmatch(s)
+    if m == nil {
+        return 0, fmt.Errorf("invalid quantity '%s'", s)
+    }
+
+    number, ok := new(big.Rat).SetString(m[1])
+    if !ok {
+        return 0, fmt.Errorf("invalid quantity '%s'", s)
+    }
+
+    mult, err := quantityMultiplier(m[2])
+    if err != nil {
+        return 0, fmt.Errorf("invalid quantity '%s'", s)
+    }
+
+    milli := new(big.Rat).Mul(number, mult)
+    milli.Mul(milli, big.NewRat(1000, 1))
+
+    f, _ := milli.Float64()
+    f = math.Round(f)
+    if f >= math.MaxInt64 || f < math.MinInt64 {
+        return 0, fmt.Errorf("quantity '%s' is out of range", s)
+    }
+    return int64(f), nil
+}
+
+func quantityMultiplier(suffix string) (*big.Rat, error) {
+    if mult, ok := binarySIMultipliers[suffix]; ok {
+        return new(big.Rat).SetInt64(mult), nil
+    }
+
+    if exp, ok := decimalSIExponents[suffix]; ok {
+        return decimalExponentRat(exp), nil
+    }
+
+    if len(suffix) > 0 && (suffix[0] == 'e' || suffix[0] == 'E') {
+        exp, err := strconv.Atoi(suffix[1:])
+        if err != nil {
+            return nil, err
+        }
+        return decimalExponentRat(exp), nil
+    }
+
+    return nil, fmt.Errorf("unsupported suffix '%s'", suffix)
+}
+
+func decimalExponentRat(exp int) *big.Rat {
+    n := exp
+    if n < 0 {
+        n = -n
+    }
+    pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+    r := new(big.Rat).SetInt(pow)
+    if exp < 0 {
+        r.Inv(r)
+    }
+    return r
+}
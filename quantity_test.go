@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+    cases := []struct {
+        in   string
+        want int64
+    }{
+        {"500m", 500},
+        {"1", 1000},
+        {"2", 2000},
+        {"1.5", 1500},
+        {"1Ki", 1024000},
+        {"1.5Gi", 1610612736000},
+        {"1500M", 1500000000000},
+        {"1000000", 1000000000},
+        {"129e6", 129000000000},
+        {"0.5Gi", 536870912000},
+    }
+
+    for _, c := range cases {
+        got, err := ParseQuantity(c.in)
+        if err != nil {
+            t.Errorf("ParseQuantity(%q) returned error: %v", c.in, err)
+            continue
+        }
+        if got != c.want {
+            t.Errorf("ParseQuantity(%q) = %d, want %d", c.in, got, c.want)
+        }
+    }
+}
+
+func TestParseQuantityInvalid(t *testing.T) {
+    for _, in := range []string{"500xy", "", "Gi", "1.5.5"} {
+        if _, err := ParseQuantity(in); err == nil {
+            t.Errorf("ParseQuantity(%q) expected error, got nil", in)
+        }
+    }
+}
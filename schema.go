@@ -0,0 +1,371 @@
+package main
+
+import (
+    _ "embed"
+    "encoding/json"
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+
+    goyaml "gopkg.in/yaml.v3"
+)
+
+//go:embed schemas/pod.schema.json
+var defaultPodSchemaJSON []byte
+
+// Schema is a declarative description of the shape a YAML node must take.
+// It covers the subset of JSON Schema / Kubernetes OpenAPI that the
+// validator needs: object/array/string/integer types, required fields,
+// enum and pattern constraints on strings, and minimum/maximum bounds on
+// numbers. $ref is resolved against the sibling "definitions" map.
+type Schema struct {
+    Type       string             `json:"type,omitempty"`
+    Properties map[string]*Schema `json:"properties,omitempty"`
+    Required   []string           `json:"required,omitempty"`
+    Items      *Schema            `json:"items,omitempty"`
+    Pattern    string             `json:"pattern,omitempty"`
+    Enum       []string           `json:"enum,omitempty"`
+    Minimum    *float64           `json:"minimum,omitempty"`
+    Maximum    *float64           `json:"maximum,omitempty"`
+    MinLength  *int               `json:"minLength,omitempty"`
+    Ref        string             `json:"$ref,omitempty"`
+
+    // Rule names the stable rule ID this field's constraints report under,
+    // e.g. "pod.container.image". Suffixed with the constraint that fired
+    // (".required", ".format", ...) to form the ValidationError's Rule.
+    Rule string `json:"rule,omitempty"`
+
+    // Format names a domain-specific validator to run on a scalar instead
+    // of the plain type/pattern checks, e.g. "quantity" for Kubernetes
+    // resource quantities and "image" for image references.
+    Format string `json:"format,omitempty"`
+
+    // Patterns, used by the "image" format, accepts the value if it
+    // matches any one of the given regexes. This is how the -config
+    // policy's allowed-registry list plugs into the schema.
+    Patterns []string `json:"patterns,omitempty"`
+
+    // ForbiddenValues, used by the "image" format, rejects the value's tag
+    // (the part after the last ':') if it appears in this list.
+    ForbiddenValues []string `json:"forbiddenValues,omitempty"`
+
+    // ListMapKeys mirrors Kubernetes' x-kubernetes-list-map-keys: fields
+    // that must be unique across the elements of an array.
+    ListMapKeys []string `json:"x-kubernetes-list-map-keys,omitempty"`
+
+    // RequiredMapKeys mirrors Required but for freeform maps such as
+    // metadata.labels, where the keys aren't declared in Properties.
+    RequiredMapKeys []string `json:"x-required-keys,omitempty"`
+
+    // CrossCheckResources enables the requests<=limits check on an object
+    // with "requests"/"limits" quantity maps, mirroring the Kubernetes
+    // admission rule that a container's limits must cover its requests.
+    CrossCheckResources bool `json:"x-cross-check-resources,omitempty"`
+}
+
+// RootSchema is the top-level document: a Schema plus the definitions it
+// may $ref into.
+type RootSchema struct {
+    Schema
+    Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// loadSchema parses schema JSON, either user-supplied via -schema or the
+// embedded default.
+func loadSchema(b []byte) (*RootSchema, error) {
+    var s RootSchema
+    if err := json.Unmarshal(b, &s); err != nil {
+        return nil, err
+    }
+    return &s, nil
+}
+
+func defaultPodSchema() (*RootSchema, error) {
+    return loadSchema(defaultPodSchemaJSON)
+}
+
+// validateAgainstSchema walks n against s, resolving $refs via defs and
+// reporting field paths relative to the document root (e.g.
+// "spec.containers[0].image").
+func validateAgainstSchema(n *goyaml.Node, s *Schema, defs map[string]*Schema, path string) []ValidationError {
+    if s.Ref != "" {
+        resolved, ok := defs[strings.TrimPrefix(s.Ref, "#/definitions/")]
+        if !ok {
+            return []ValidationError{{Msg: fmt.Sprintf("%s: unresolved schema ref '%s'", path, s.Ref), Rule: "yamlvalidator.schema"}}
+        }
+        return validateAgainstSchema(n, resolved, defs, path)
+    }
+
+    if s.Format == "quantity" {
+        return validateQuantity(n, s, path)
+    }
+    if s.Format == "image" {
+        return validateImage(n, s, path)
+    }
+
+    if !typeMatches(n, s.Type) {
+        return []ValidationError{{Msg: fmt.Sprintf("%s must be %s", path, typeWord(s.Type)), Line: n.Line, Column: n.Column, Rule: ruleID(s, "type")}}
+    }
+
+    switch s.Type {
+    case "object":
+        return validateObject(n, s, defs, path)
+    case "array":
+        return validateArray(n, s, defs, path)
+    case "string":
+        return validateString(n, s, path)
+    case "integer", "number":
+        return validateNumber(n, s, path)
+    default:
+        return nil
+    }
+}
+
+func validateObject(n *goyaml.Node, s *Schema, defs map[string]*Schema, path string) []ValidationError {
+    var r []ValidationError
+
+    for _, field := range s.Required {
+        child := getMapValue(n, field)
+        childPath := joinPath(path, field)
+        fieldSchema := s.Properties[field]
+        if child == nil || (fieldSchema != nil && isBlank(child, fieldSchema)) {
+            line, col, rule := 0, 0, ruleID(s, "required")
+            if child != nil {
+                line, col = child.Line, child.Column
+            }
+            if fieldSchema != nil {
+                rule = ruleID(fieldSchema, "required")
+            }
+            r = append(r, ValidationError{Msg: fmt.Sprintf("%s is required", childPath), Line: line, Column: col, Rule: rule})
+        }
+    }
+
+    for _, key := range s.RequiredMapKeys {
+        if getMapValue(n, key) == nil {
+            r = append(r, ValidationError{Msg: fmt.Sprintf("%s is required", joinPath(path, key)), Rule: ruleID(s, "requiredKey")})
+        }
+    }
+
+    for name, fieldSchema := range s.Properties {
+        child := getMapValue(n, name)
+        if child == nil {
+            continue
+        }
+        r = append(r, validateAgainstSchema(child, fieldSchema, defs, joinPath(path, name))...)
+    }
+
+    if s.CrossCheckResources {
+        r = append(r, validateRequestsWithinLimits(n, s, path)...)
+    }
+
+    return r
+}
+
+// validateRequestsWithinLimits enforces that, for cpu and memory where
+// present in both requests and limits, requests does not exceed limits.
+// Other resource names are not compared.
+func validateRequestsWithinLimits(n *goyaml.Node, s *Schema, path string) []ValidationError {
+    req := getMapValue(n, "requests")
+    lim := getMapValue(n, "limits")
+    if req == nil || lim == nil {
+        return nil
+    }
+
+    var r []ValidationError
+    for _, key := range []string{"cpu", "memory"} {
+        rq := getMapValue(req, key)
+        lq := getMapValue(lim, key)
+        if rq == nil || lq == nil {
+            continue
+        }
+
+        rv, err := ParseQuantity(rq.Value)
+        if err != nil {
+            continue
+        }
+        lv, err := ParseQuantity(lq.Value)
+        if err != nil {
+            continue
+        }
+
+        if rv > lv {
+            reqPath := joinPath(joinPath(path, "requests"), key)
+            limPath := joinPath(joinPath(path, "limits"), key)
+            r = append(r, ValidationError{Msg: fmt.Sprintf("%s exceeds %s", reqPath, limPath), Line: rq.Line, Column: rq.Column, Rule: ruleID(s, "requestsExceedLimits")})
+        }
+    }
+
+    return r
+}
+
+func validateQuantity(n *goyaml.Node, s *Schema, path string) []ValidationError {
+    if n.Kind != goyaml.ScalarNode {
+        return []ValidationError{{Msg: fmt.Sprintf("%s must be a quantity", path), Line: n.Line, Column: n.Column, Rule: ruleID(s, "quantity")}}
+    }
+
+    if _, err := ParseQuantity(n.Value); err != nil {
+        return []ValidationError{{Msg: fmt.Sprintf("%s has invalid quantity '%s'", path, n.Value), Line: n.Line, Column: n.Column, Rule: ruleID(s, "quantity")}}
+    }
+
+    return nil
+}
+
+// validateImage checks an image reference against the allowed registry
+// patterns and, if configured, rejects forbidden tags (e.g. "latest").
+func validateImage(n *goyaml.Node, s *Schema, path string) []ValidationError {
+    if n.Kind != goyaml.ScalarNode {
+        return []ValidationError{{Msg: fmt.Sprintf("%s must be a string", path), Line: n.Line, Column: n.Column, Rule: ruleID(s, "type")}}
+    }
+
+    matched := false
+    for _, p := range s.Patterns {
+        if regexp.MustCompile(p).MatchString(n.Value) {
+            matched = true
+            break
+        }
+    }
+    if !matched {
+        return []ValidationError{{Msg: fmt.Sprintf("%s has invalid format '%s'", path, n.Value), Line: n.Line, Column: n.Column, Rule: ruleID(s, "format")}}
+    }
+
+    if len(s.ForbiddenValues) > 0 {
+        if tag := imageTag(n.Value); contains(s.ForbiddenValues, tag) {
+            return []ValidationError{{Msg: fmt.Sprintf("%s has forbidden tag '%s'", path, tag), Line: n.Line, Column: n.Column, Rule: ruleID(s, "forbiddenTag")}}
+        }
+    }
+
+    return nil
+}
+
+func imageTag(image string) string {
+    idx := strings.LastIndex(image, ":")
+    if idx == -1 {
+        return ""
+    }
+    return image[idx+1:]
+}
+
+func validateArray(n *goyaml.Node, s *Schema, defs map[string]*Schema, path string) []ValidationError {
+    var r []ValidationError
+
+    seen := map[string]bool{}
+    for i, item := range n.Content {
+        itemPath := fmt.Sprintf("%s[%d]", path, i)
+        if s.Items != nil {
+            r = append(r, validateAgainstSchema(item, s.Items, defs, itemPath)...)
+        }
+        for _, key := range s.ListMapKeys {
+            kv := getMapValue(item, key)
+            if kv == nil || kv.Value == "" {
+                continue
+            }
+            keyRule := ruleID(s, "unique")
+            if s.Items != nil {
+                if keySchema, ok := s.Items.Properties[key]; ok {
+                    keyRule = ruleID(keySchema, "unique")
+                }
+            }
+            seenKey := key + "\x00" + kv.Value
+            if seen[seenKey] {
+                r = append(r, ValidationError{Msg: fmt.Sprintf("%s has invalid format '%s'", joinPath(path, key), kv.Value), Line: kv.Line, Column: kv.Column, Rule: keyRule})
+            } else {
+                seen[seenKey] = true
+            }
+        }
+    }
+
+    return r
+}
+
+func validateString(n *goyaml.Node, s *Schema, path string) []ValidationError {
+    var r []ValidationError
+
+    if len(s.Enum) > 0 && !contains(s.Enum, n.Value) {
+        r = append(r, ValidationError{Msg: fmt.Sprintf("%s has unsupported value '%s'", path, n.Value), Line: n.Line, Column: n.Column, Rule: ruleID(s, "enum")})
+        return r
+    }
+
+    if s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(n.Value) {
+        r = append(r, ValidationError{Msg: fmt.Sprintf("%s has invalid format '%s'", path, n.Value), Line: n.Line, Column: n.Column, Rule: ruleID(s, "format")})
+    }
+
+    return r
+}
+
+func validateNumber(n *goyaml.Node, s *Schema, path string) []ValidationError {
+    var r []ValidationError
+
+    v, err := strconv.ParseFloat(n.Value, 64)
+    if err != nil {
+        return nil
+    }
+
+    if (s.Minimum != nil && v < *s.Minimum) || (s.Maximum != nil && v > *s.Maximum) {
+        r = append(r, ValidationError{Msg: fmt.Sprintf("%s value out of range", path), Line: n.Line, Column: n.Column, Rule: ruleID(s, "range")})
+    }
+
+    return r
+}
+
+// ruleID builds the stable rule ID a constraint on s reports under. Schemas
+// without an explicit "rule" fall back to a generic yamlvalidator bucket so
+// every finding still carries a suppressible rule ID.
+func ruleID(s *Schema, constraint string) string {
+    if s.Rule == "" {
+        return "yamlvalidator." + constraint
+    }
+    return s.Rule + "." + constraint
+}
+
+func typeMatches(n *goyaml.Node, t string) bool {
+    switch t {
+    case "object":
+        return n.Kind == goyaml.MappingNode
+    case "array":
+        return n.Kind == goyaml.SequenceNode
+    case "string":
+        return n.Kind == goyaml.ScalarNode && n.Tag == "!!str"
+    case "integer":
+        return n.Kind == goyaml.ScalarNode && n.Tag == "!!int"
+    case "number":
+        return n.Kind == goyaml.ScalarNode && (n.Tag == "!!int" || n.Tag == "!!float")
+    case "boolean":
+        return n.Kind == goyaml.ScalarNode && n.Tag == "!!bool"
+    default:
+        return true
+    }
+}
+
+func typeWord(t string) string {
+    switch t {
+    case "integer":
+        return "int"
+    case "boolean":
+        return "bool"
+    default:
+        return t
+    }
+}
+
+// isBlank reports whether a node's value is shorter than s's minLength,
+// i.e. whether it should be treated the same as a missing required field.
+func isBlank(n *goyaml.Node, s *Schema) bool {
+    return s.MinLength != nil && len(n.Value) < *s.MinLength
+}
+
+func joinPath(base, field string) string {
+    if base == "" {
+        return field
+    }
+    return base + "." + field
+}
+
+func contains(list []string, v string) bool {
+    for _, item := range list {
+        if item == v {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+    "testing"
+
+    goyaml "gopkg.in/yaml.v3"
+)
+
+func parseNode(t *testing.T, doc string) *goyaml.Node {
+    t.Helper()
+    var root goyaml.Node
+    if err := goyaml.Unmarshal([]byte(doc), &root); err != nil {
+        t.Fatalf("unmarshal %q: %v", doc, err)
+    }
+    return root.Content[0]
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestValidateObjectRequiredAndBlank(t *testing.T) {
+    schema := &Schema{
+        Type:     "object",
+        Rule:     "test",
+        Required: []string{"name", "age"},
+        Properties: map[string]*Schema{
+            "name": {Type: "string", MinLength: intPtr(1), Rule: "test.name"},
+            "age":  {Type: "integer", Rule: "test.age"},
+        },
+    }
+
+    n := parseNode(t, "name: \"\"\n")
+    errs := validateAgainstSchema(n, schema, nil, "")
+    if len(errs) != 2 {
+        t.Fatalf("expected 2 errors (blank name + missing age), got %d: %+v", len(errs), errs)
+    }
+}
+
+func TestValidateStringEnum(t *testing.T) {
+    schema := &Schema{Type: "string", Enum: []string{"TCP", "UDP"}, Rule: "proto"}
+
+    if errs := validateAgainstSchema(parseNode(t, "UDP\n"), schema, nil, "protocol"); len(errs) != 0 {
+        t.Fatalf("unexpected errors for valid enum value: %+v", errs)
+    }
+
+    errs := validateAgainstSchema(parseNode(t, "SCTP\n"), schema, nil, "protocol")
+    if len(errs) != 1 || errs[0].Rule != "proto.enum" {
+        t.Fatalf("expected proto.enum violation, got %+v", errs)
+    }
+}
+
+func TestValidateNumberRange(t *testing.T) {
+    min, max := 1.0, 65535.0
+    schema := &Schema{Type: "integer", Minimum: &min, Maximum: &max, Rule: "port"}
+
+    errs := validateAgainstSchema(parseNode(t, "70000\n"), schema, nil, "containerPort")
+    if len(errs) != 1 || errs[0].Rule != "port.range" {
+        t.Fatalf("expected port.range violation, got %+v", errs)
+    }
+}
+
+func TestValidateArrayListMapKeysUnique(t *testing.T) {
+    schema := &Schema{
+        Type:        "array",
+        Rule:        "containers",
+        ListMapKeys: []string{"name"},
+        Items: &Schema{
+            Type: "object",
+            Properties: map[string]*Schema{
+                "name": {Type: "string", Rule: "container.name"},
+            },
+        },
+    }
+
+    errs := validateAgainstSchema(parseNode(t, "- name: app\n- name: app\n"), schema, nil, "spec.containers")
+    if len(errs) != 1 || errs[0].Rule != "container.name.unique" {
+        t.Fatalf("expected duplicate name error, got %+v", errs)
+    }
+}
+
+func TestValidateRefResolution(t *testing.T) {
+    defs := map[string]*Schema{
+        "probe": {
+            Type:     "object",
+            Rule:     "probe",
+            Required: []string{"httpGet"},
+            Properties: map[string]*Schema{
+                "httpGet": {Type: "object", Rule: "probe.httpGet"},
+            },
+        },
+    }
+    schema := &Schema{Ref: "#/definitions/probe"}
+
+    errs := validateAgainstSchema(parseNode(t, "{}\n"), schema, defs, "livenessProbe")
+    if len(errs) != 1 || errs[0].Rule != "probe.httpGet.required" {
+        t.Fatalf("expected httpGet required error via $ref, got %+v", errs)
+    }
+}
+
+func TestValidateRequestsExceedLimits(t *testing.T) {
+    schema := &Schema{
+        Type:                "object",
+        Rule:                "resources",
+        CrossCheckResources: true,
+        Properties: map[string]*Schema{
+            "requests": {Type: "object", Properties: map[string]*Schema{"cpu": {Format: "quantity"}}},
+            "limits":   {Type: "object", Properties: map[string]*Schema{"cpu": {Format: "quantity"}}},
+        },
+    }
+
+    errs := validateAgainstSchema(parseNode(t, "requests:\n  cpu: 500m\nlimits:\n  cpu: 100m\n"), schema, nil, "resources")
+    found := false
+    for _, e := range errs {
+        if e.Rule == "resources.requestsExceedLimits" {
+            found = true
+        }
+    }
+    if !found {
+        t.Fatalf("expected requests>limits violation, got %+v", errs)
+    }
+}
+
+func TestValidateQuantityFormat(t *testing.T) {
+    schema := &Schema{Format: "quantity", Rule: "cpu"}
+
+    errs := validateAgainstSchema(parseNode(t, "500xy\n"), schema, nil, "cpu")
+    if len(errs) != 1 || errs[0].Rule != "cpu.quantity" {
+        t.Fatalf("expected cpu.quantity violation, got %+v", errs)
+    }
+}
+
+func TestValidateImageFormat(t *testing.T) {
+    schema := &Schema{Format: "image", Patterns: []string{`^registry\.bigbrother\.io/[^:]+:[^:]+$`}, Rule: "image"}
+
+    if errs := validateAgainstSchema(parseNode(t, "registry.bigbrother.io/app:v1\n"), schema, nil, "image"); len(errs) != 0 {
+        t.Fatalf("unexpected errors for valid image: %+v", errs)
+    }
+
+    schema.ForbiddenValues = []string{"latest"}
+    errs := validateAgainstSchema(parseNode(t, "registry.bigbrother.io/app:latest\n"), schema, nil, "image")
+    if len(errs) != 1 || errs[0].Rule != "image.forbiddenTag" {
+        t.Fatalf("expected image.forbiddenTag violation, got %+v", errs)
+    }
+}